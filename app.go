@@ -9,16 +9,18 @@ import (
 	"ro-server-player-monitor/go/github"
 	"ro-server-player-monitor/go/network"
 	"ro-server-player-monitor/go/ragnarok"
+	"time"
 
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 // App struct
 type App struct {
-	ctx                  context.Context
-	services             AppServices
-	isCapturing          bool
-	packetCaptureService *network.PacketCaptureService
+	ctx                     context.Context
+	services                AppServices
+	isCapturing             bool
+	packetCaptureService    *network.PacketCaptureService
+	captureRecordingEnabled bool
 
 	appVersion string
 }
@@ -112,6 +114,23 @@ func (a *App) buildConfigPath() string {
 	return configPath
 }
 
+// buildCaptureRecordingDir returns the directory recorded .pcap files are
+// written to, alongside config.xml.
+func (a *App) buildCaptureRecordingDir() string {
+	inDevMode := runtime.Environment(a.ctx).BuildType == "development"
+
+	var basePath string
+	if inDevMode {
+		// in development mode, use the current working directory
+		basePath, _ = os.Getwd()
+	} else {
+		// in production mode, use the executable path
+		basePath, _ = os.Executable()
+	}
+	dir := filepath.Dir(basePath)
+	return filepath.Join(dir, "captures")
+}
+
 // CheckForUpdate checks if there is a newer version of the application available.
 // It retrieves the latest release tag from GitHub and compares it with the current version.
 // If a newer version is available, it returns the latest tag string.
@@ -146,6 +165,17 @@ func (a *App) CheckForUpdate() string {
 	}
 }
 
+// findLoginServer returns a pointer to the entry in loginServers whose
+// Name matches targetServerName, or nil if none does.
+func findLoginServer(targetServerName string) *LoginServer {
+	for i, server := range loginServers {
+		if server.Name == targetServerName {
+			return &loginServers[i]
+		}
+	}
+	return nil
+}
+
 // GetServers returns the list of servers
 func (a *App) GetLoginServers() []LoginServer {
 	runtime.LogInfof(a.ctx, "[App.GetLoginServers] loginServers: %+v", loginServers)
@@ -173,16 +203,162 @@ func (a *App) StopCapture() bool {
 	return true
 }
 
-// StartCapture initiates a packet capture session targeting the specified Ragnarok Online server.
-// It first checks if a capture session is already running and stops it if necessary. Then, it constructs
-// a network filter based on the provided targetServer name, matching it against the known loginServers.
-// If a matching server is found, it starts capturing packets on all interfaces using the constructed filter.
-// The function listens for packets on the capture channel, and upon receiving a packet that matches the
-// expected pattern, it parses the payload into a list of CharacterServerInfo objects, stops the capture,
-// and returns the list. If no matching server is found or the context is done, it returns nil.
+// newDissectorRegistry builds the ragnarok.Registry tried against every
+// reassembled stream for a capture session targeting server. It registers
+// the built-in character-server-list dissector keyed by server's Pattern
+// and IsNumberResponse, preserving today's behavior; supporting another
+// server->client packet is a matter of registering another ragnarok.Dissector
+// here, not adding another special case to App.
+func newDissectorRegistry(server *LoginServer) *ragnarok.Registry {
+	registry := ragnarok.NewRegistry()
+	registry.Register(&ragnarok.CharacterServerDissector{
+		Pattern:          server.Pattern,
+		IsNumberResponse: server.IsNumberResponse,
+	})
+	return registry
+}
+
+// startEventLoop spawns a goroutine that forwards packetCaptureService's
+// notification channels to the Wails frontend as capture:* runtime events,
+// until the service's context is done:
+//   - capture:interface-opened once the selected interface is ready
+//   - capture:connection-new for every new 4-tuple seen
+//   - capture:connection-closed with byte/elapsed-time stats once a
+//     connection finishes
+//   - capture:parse-success with a discriminated-union result (keyed by
+//     dissector name) once any registry dissector matches a closed
+//     connection's reassembled payload
+//   - capture:error for any non-fatal capture error
+//
+// If resultChan is non-nil and the character-server-list dissector
+// matched, its result is also delivered on resultChan (used by the
+// synchronous StartCapture wrapper); async callers should pass nil and
+// listen for capture:parse-success instead.
+func (a *App) startEventLoop(packetCaptureService *network.PacketCaptureService, registry *ragnarok.Registry, resultChan chan<- []CharacterServerInfo) {
+	ctx := packetCaptureService.GetContext()
+	interfaceOpenedChannel := packetCaptureService.GetInterfaceOpenedChannel()
+	connNewChannel := packetCaptureService.GetConnectionNewNotifyChannel()
+	connCloseChannel := packetCaptureService.GetConnectionCloseNotifyChannel()
+	errorChannel := packetCaptureService.GetErrorChannel()
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case ifaceName := <-interfaceOpenedChannel:
+				runtime.EventsEmit(a.ctx, "capture:interface-opened", map[string]any{
+					"interface": ifaceName,
+				})
+
+			case connection := <-connNewChannel:
+				runtime.EventsEmit(a.ctx, "capture:connection-new", map[string]any{
+					"srcIP":   connection.SrcIP.String(),
+					"dstIP":   connection.DstIP.String(),
+					"srcPort": connection.SrcPort,
+					"dstPort": connection.DstPort,
+				})
+
+			case connection := <-connCloseChannel:
+				elapsed := time.Since(connection.StartTime)
+				payload := connection.Bytes()
+
+				runtime.EventsEmit(a.ctx, "capture:connection-closed", map[string]any{
+					"srcIP":     connection.SrcIP.String(),
+					"dstIP":     connection.DstIP.String(),
+					"srcPort":   connection.SrcPort,
+					"dstPort":   connection.DstPort,
+					"bytes":     len(payload),
+					"elapsedMs": elapsed.Milliseconds(),
+				})
+
+				results := registry.Dissect(payload)
+				if len(results) > 0 {
+					runtime.EventsEmit(a.ctx, "capture:parse-success", results)
+
+					if resultChan != nil {
+						if charServerInfoList, ok := results["character-server-list"].([]CharacterServerInfo); ok {
+							resultChan <- charServerInfoList
+						}
+					}
+
+					packetCaptureService.StopCapture()
+					return
+				}
+
+			case err := <-errorChannel:
+				runtime.EventsEmit(a.ctx, "capture:error", map[string]any{
+					"message": err.Error(),
+				})
+			}
+		}
+	}()
+}
+
+// StartCaptureAsync initiates a packet capture session targeting the
+// specified Ragnarok Online server and returns immediately once capture
+// has started; progress (interfaces opened, connections opened/closed,
+// parse results, errors) is reported via capture:* Wails runtime events
+// through startEventLoop instead of blocking the caller. Use CancelCapture
+// to stop it.
+//
+// Parameters:
+//   - targetServerName: The name of the server to capture packets from.
+//
+// Returns:
+//   - bool: true if capture was started, false if no matching server was found.
+func (a *App) StartCaptureAsync(targetServerName string) bool {
+	runtime.LogInfof(a.ctx, "[App.StartCaptureAsync] entering with targetServer: %s ...", targetServerName)
+
+	if a.isCapturing || a.packetCaptureService != nil {
+		runtime.LogWarningf(a.ctx, "[App.StartCaptureAsync] Already capturing, stop the previous capture.")
+
+		a.packetCaptureService.StopCapture()
+		a.isCapturing = false
+		a.packetCaptureService = nil
+	}
+
+	targetServer := findLoginServer(targetServerName)
+	if targetServer == nil {
+		runtime.LogWarningf(a.ctx, "[App.StartCaptureAsync] No matching server found for server name: %s", targetServerName)
+		return false
+	}
+	runtime.LogInfof(a.ctx, "[App.StartCaptureAsync] confirm target server: %s", targetServer.Name)
+
+	packetCaptureService := network.NewPacketCaptureService(targetServer.IP, targetServer.Port)
+
+	a.packetCaptureService = packetCaptureService
+	a.isCapturing = true
+
+	if a.captureRecordingEnabled {
+		recordingDir := a.buildCaptureRecordingDir()
+		if err := packetCaptureService.EnableRecording(recordingDir); err != nil {
+			runtime.LogErrorf(a.ctx, "[App.StartCaptureAsync] Failed to enable capture recording: %v", err)
+		}
+	}
+
+	a.startEventLoop(packetCaptureService, newDissectorRegistry(targetServer), nil)
+	packetCaptureService.StartCaptureAllInterfaces()
+
+	return true
+}
+
+// CancelCapture stops an in-progress capture started via
+// StartCaptureAsync. It behaves identically to StopCapture; the separate
+// name exists so the async API reads as a matching pair.
+func (a *App) CancelCapture() bool {
+	return a.StopCapture()
+}
+
+// StartCapture is the synchronous counterpart of StartCaptureAsync, kept for
+// backwards compatibility: it starts the same event-driven capture, then
+// blocks until startEventLoop reports a successful parse (or the capture's
+// context ends) and returns the result directly instead of requiring the
+// caller to listen for capture:parse-success.
 //
 // Parameters:
-//   - targetServer: The name of the server to capture packets from.
+//   - targetServerName: The name of the server to capture packets from.
 //
 // Returns:
 //   - []CharacterServerInfo: A slice containing parsed character server information, or nil if no data is captured.
@@ -192,73 +368,178 @@ func (a *App) StartCapture(targetServerName string) []CharacterServerInfo {
 	if a.isCapturing || a.packetCaptureService != nil {
 		runtime.LogWarningf(a.ctx, "[App.StartCapture] Already capturing, stop the previous capture.")
 
-		// stop the running packet capture service if it exists
 		a.packetCaptureService.StopCapture()
-
-		// reset isCapturing flag and clean up packetCaptureService reference
 		a.isCapturing = false
 		a.packetCaptureService = nil
 	}
 
-	// find the target server in loginServers based on targetServerName
-	var targetServer *LoginServer
-	for _, server := range loginServers {
-		if server.Name == targetServerName {
-			targetServer = &server
-			runtime.LogInfof(a.ctx, "[App.StartCapture] confirm target server: %s", targetServer.Name)
-			break
-		}
-	}
-
-	// if targetServer is nil, it means no matching server found
+	targetServer := findLoginServer(targetServerName)
 	if targetServer == nil {
-		// TODO: add error handling, show a warning dialog to user
 		runtime.LogWarningf(a.ctx, "[App.StartCapture] No matching server found for server name: %s", targetServerName)
 		return nil
 	}
+	runtime.LogInfof(a.ctx, "[App.StartCapture] confirm target server: %s", targetServer.Name)
 
-	// construct the net filter for packet capture service by targetServer
-	// filter := fmt.Sprintf("tcp and net %s and port %d", targetServer.IP, targetServer.Port)
-	// runtime.LogInfof(a.ctx, "[App.StartCapture] build filter success: %s", filter)
-	pattern := targetServer.Pattern
-
-	// create a new packet capture service with the target server's IP and port
 	packetCaptureService := network.NewPacketCaptureService(targetServer.IP, targetServer.Port)
 	ctx := packetCaptureService.GetContext()
-	channel := packetCaptureService.GetConnectionCloseNotifyChannel()
 
-	// memorize the packetCaptureService and turn on isCapturing flag
 	a.packetCaptureService = packetCaptureService
 	a.isCapturing = true
 
-	// start the packet capture service
+	if a.captureRecordingEnabled {
+		recordingDir := a.buildCaptureRecordingDir()
+		if err := packetCaptureService.EnableRecording(recordingDir); err != nil {
+			runtime.LogErrorf(a.ctx, "[App.StartCapture] Failed to enable capture recording: %v", err)
+		}
+	}
+
+	resultChan := make(chan []CharacterServerInfo, 1)
+	a.startEventLoop(packetCaptureService, newDissectorRegistry(targetServer), resultChan)
 	packetCaptureService.StartCaptureAllInterfaces()
 
-	for {
-		select {
-		case connection := <-channel:
-			// handle the connection close notification
+	select {
+	case result := <-resultChan:
+		return result
+	case <-ctx.Done():
+		return nil
+	}
+}
 
-			sortedIncomingData := connection.GetIncomingDataSortedByLength()
+// EnableCaptureRecording turns on (or off) mirroring of every captured
+// packet to a timestamped .pcap file under the app's "captures" directory.
+// It takes effect on the next StartCapture call, so callers report bugs
+// against a saved trace instead of requiring a live repro against the real
+// server.
+func (a *App) EnableCaptureRecording(enabled bool) bool {
+	a.captureRecordingEnabled = enabled
+	runtime.LogInfof(a.ctx, "[App.EnableCaptureRecording] enabled: %v", enabled)
+	return a.captureRecordingEnabled
+}
 
-			for _, data := range sortedIncomingData {
-				charServerInfoList := ragnarok.ParsePayloadToCharacterServerInfo(data, pattern)
-				runtime.LogInfof(a.ctx, "[App.StartCapture] charServerInfoList: %+v", charServerInfoList)
+// StartCaptureFromFile replays a previously recorded .pcap file (e.g. one
+// saved via EnableCaptureRecording, or attached to a bug report) through
+// the same parsing pipeline as a live capture, targeting the given
+// targetServerName's IP/port/pattern. Like StartCapture, progress is
+// reported via capture:* Wails runtime events through startEventLoop while
+// this call blocks until a successful parse (or the replay finishing
+// without one).
+//
+// Parameters:
+//   - path: filesystem path to the .pcap file to replay.
+//   - targetServerName: the name of the server to match packets against.
+//
+// Returns:
+//   - []CharacterServerInfo: parsed character server information, or nil if none was found.
+func (a *App) StartCaptureFromFile(path string, targetServerName string) []CharacterServerInfo {
+	runtime.LogInfof(a.ctx, "[App.StartCaptureFromFile] entering with path: %s, targetServer: %s ...", path, targetServerName)
 
-				if charServerInfoList != nil {
-					// stop the packet capture service
-					packetCaptureService.StopCapture()
-					// return the charServerInfoList
-					return charServerInfoList
-				}
-			}
+	if a.isCapturing || a.packetCaptureService != nil {
+		runtime.LogWarningf(a.ctx, "[App.StartCaptureFromFile] Already capturing, stop the previous capture.")
+
+		a.packetCaptureService.StopCapture()
+		a.isCapturing = false
+		a.packetCaptureService = nil
+	}
+
+	// find the target server in loginServers based on targetServerName
+	targetServer := findLoginServer(targetServerName)
+
+	if targetServer == nil {
+		runtime.LogWarningf(a.ctx, "[App.StartCaptureFromFile] No matching server found for server name: %s", targetServerName)
+		return nil
+	}
+	runtime.LogInfof(a.ctx, "[App.StartCaptureFromFile] confirm target server: %s", targetServer.Name)
+
+	packetCaptureService := network.NewOfflineCaptureService(path, targetServer.IP, targetServer.Port)
+	ctx := packetCaptureService.GetContext()
+
+	a.packetCaptureService = packetCaptureService
+	a.isCapturing = true
+
+	resultChan := make(chan []CharacterServerInfo, 1)
+	a.startEventLoop(packetCaptureService, newDissectorRegistry(targetServer), resultChan)
+
+	go func() {
+		if err := packetCaptureService.StartReplay(); err != nil {
+			runtime.LogErrorf(a.ctx, "[App.StartCaptureFromFile] Replay failed: %v", err)
+			packetCaptureService.StopCapture()
+		}
+	}()
+
+	select {
+	case result := <-resultChan:
+		return result
+	case <-ctx.Done():
+		return nil
+	}
+}
+
+// GetCaptureInterfaces returns every valid capture interface, enriched
+// with whether it plausibly routes to targetServerName's IP, so the
+// frontend can let a user pick one explicitly via StartCaptureOnInterface
+// instead of relying on auto-detection.
+func (a *App) GetCaptureInterfaces(targetServerName string) []network.InterfaceInfo {
+	targetServer := findLoginServer(targetServerName)
+	if targetServer == nil {
+		runtime.LogWarningf(a.ctx, "[App.GetCaptureInterfaces] No matching server found for server name: %s", targetServerName)
+		return nil
+	}
+
+	interfaces, err := network.ListInterfaces(targetServer.IP)
+	if err != nil {
+		runtime.LogErrorf(a.ctx, "[App.GetCaptureInterfaces] Failed to list interfaces: %v", err)
+		return nil
+	}
+
+	return interfaces
+}
+
+// StartCaptureOnInterface behaves like StartCapture but opens only the
+// named interface (as returned by GetCaptureInterfaces) instead of
+// auto-selecting one, for when a user needs to override auto-detection.
+// Like StartCapture, progress is reported via capture:* Wails runtime
+// events through startEventLoop while this call blocks for the result.
+func (a *App) StartCaptureOnInterface(targetServerName string, ifaceName string) []CharacterServerInfo {
+	runtime.LogInfof(a.ctx, "[App.StartCaptureOnInterface] entering with targetServer: %s, interface: %s ...", targetServerName, ifaceName)
+
+	if a.isCapturing || a.packetCaptureService != nil {
+		runtime.LogWarningf(a.ctx, "[App.StartCaptureOnInterface] Already capturing, stop the previous capture.")
+
+		a.packetCaptureService.StopCapture()
+		a.isCapturing = false
+		a.packetCaptureService = nil
+	}
 
-		case <-ctx.Done():
-			// handle context done signal
-			return nil
+	targetServer := findLoginServer(targetServerName)
+	if targetServer == nil {
+		runtime.LogWarningf(a.ctx, "[App.StartCaptureOnInterface] No matching server found for server name: %s", targetServerName)
+		return nil
+	}
+	runtime.LogInfof(a.ctx, "[App.StartCaptureOnInterface] confirm target server: %s", targetServer.Name)
+
+	packetCaptureService := network.NewPacketCaptureService(targetServer.IP, targetServer.Port)
+	ctx := packetCaptureService.GetContext()
+
+	a.packetCaptureService = packetCaptureService
+	a.isCapturing = true
+
+	if a.captureRecordingEnabled {
+		recordingDir := a.buildCaptureRecordingDir()
+		if err := packetCaptureService.EnableRecording(recordingDir); err != nil {
+			runtime.LogErrorf(a.ctx, "[App.StartCaptureOnInterface] Failed to enable capture recording: %v", err)
 		}
 	}
 
+	resultChan := make(chan []CharacterServerInfo, 1)
+	a.startEventLoop(packetCaptureService, newDissectorRegistry(targetServer), resultChan)
+	packetCaptureService.StartCaptureOnInterface(ifaceName)
+
+	select {
+	case result := <-resultChan:
+		return result
+	case <-ctx.Done():
+		return nil
+	}
 }
 
 func (a *App) OpenGitHub() {
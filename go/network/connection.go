@@ -1,12 +1,11 @@
 package network
 
 import (
+	"bytes"
 	"net"
 	"time"
 )
 
-type Payload = []byte
-
 type ConnectionKey struct {
 	SrcIP   string
 	DstIP   string
@@ -14,6 +13,11 @@ type ConnectionKey struct {
 	DstPort uint16
 }
 
+// Connection tracks one TCP 4-tuple between the monitored client and a
+// login/char server. IncomingData holds the fully reassembled
+// server->client byte stream, built segment-by-segment (and, when segments
+// arrive out of order or are retransmitted, reordered) by the tcpassembly
+// pipeline (tcpStream in stream.go) rather than appended verbatim.
 type Connection struct {
 	SrcIP        net.IP
 	DstIP        net.IP
@@ -21,8 +25,8 @@ type Connection struct {
 	DstPort      uint16
 	StartTime    time.Time
 	LastSeen     time.Time
-	IncomingData []Payload // array of incoming payload
-	IsFinished   bool      // flag to indicate if the connection is finished
+	IncomingData bytes.Buffer // reassembled server->client byte stream
+	IsFinished   bool         // flag to indicate if the connection is finished
 }
 
 func (c *Connection) Key() ConnectionKey {
@@ -34,23 +38,7 @@ func (c *Connection) Key() ConnectionKey {
 	}
 }
 
-func (c *Connection) GetIncomingDataSortedByLength() []Payload {
-	if len(c.IncomingData) == 0 {
-		return nil
-	}
-
-	// Create a copy of the IncomingData slice
-	sortedPackets := make([]Payload, len(c.IncomingData))
-	copy(sortedPackets, c.IncomingData)
-
-	// Sort the packets by their length in descending order
-	for i := 0; i < len(sortedPackets)-1; i++ {
-		for j := i + 1; j < len(sortedPackets); j++ {
-			if len(sortedPackets[i]) < len(sortedPackets[j]) {
-				sortedPackets[i], sortedPackets[j] = sortedPackets[j], sortedPackets[i]
-			}
-		}
-	}
-
-	return sortedPackets
+// Bytes returns the reassembled server->client payload captured so far.
+func (c *Connection) Bytes() []byte {
+	return c.IncomingData.Bytes()
 }
@@ -0,0 +1,137 @@
+package network
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/google/gopacket/pcap"
+)
+
+// InterfaceInfo enriches a pcap.Interface with the addresses net.Interfaces
+// knows about for it, plus whether its subnet plausibly routes to the
+// server IP ListInterfaces was asked about.
+type InterfaceInfo struct {
+	Name          string
+	Description   string
+	Addresses     []string
+	IsLikelyRoute bool
+}
+
+// IsValidInterface reports whether device is a usable, non-loopback
+// capture target.
+func IsValidInterface(device pcap.Interface) bool {
+	if len(device.Addresses) == 0 {
+		return false
+	}
+
+	for _, addr := range device.Addresses {
+		if addr.IP.IsLoopback() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ListInterfaces returns every valid capture-able interface, enriched with
+// its addresses and whether its subnet plausibly routes to targetIP (a
+// literal address or a hostname - see resolveIPs - so a dual-stack server
+// with both A and AAAA records is matched against either family). This
+// lets callers (notably App.GetCaptureInterfaces) offer users a specific
+// interface to capture on instead of opening every non-loopback device,
+// which on Windows machines with many virtual adapters (VPN, WSL, Hyper-V,
+// VirtualBox) wastes handles and can trigger UAC/driver warnings.
+func ListInterfaces(targetIP string) ([]InterfaceInfo, error) {
+	devices, err := pcap.FindAllDevs()
+	if err != nil {
+		return nil, fmt.Errorf("[Network.ListInterfaces] unable to list devices: %w", err)
+	}
+
+	// best-effort: an unresolvable hostname just means no interface gets
+	// flagged as the likely route, not a hard failure to list interfaces
+	targets, _ := resolveIPs(targetIP)
+
+	// the local source address the OS routing table picks toward each
+	// target; dialing UDP selects a route without sending any packet
+	var routeSrcs []net.IP
+	for _, target := range targets {
+		if conn, err := net.Dial("udp", net.JoinHostPort(target.String(), "80")); err == nil {
+			routeSrcs = append(routeSrcs, conn.LocalAddr().(*net.UDPAddr).IP)
+			conn.Close()
+		}
+	}
+
+	infos := make([]InterfaceInfo, 0, len(devices))
+	for _, device := range devices {
+		if !IsValidInterface(device) {
+			continue
+		}
+
+		info := InterfaceInfo{
+			Name:        device.Name,
+			Description: device.Description,
+		}
+
+		for _, addr := range device.Addresses {
+			info.Addresses = append(info.Addresses, addr.IP.String())
+
+			for _, target := range targets {
+				if subnetContains(addr, target) {
+					info.IsLikelyRoute = true
+				}
+			}
+			for _, src := range routeSrcs {
+				if addr.IP.Equal(src) {
+					info.IsLikelyRoute = true
+				}
+			}
+		}
+
+		infos = append(infos, info)
+	}
+
+	return infos, nil
+}
+
+// subnetContains reports whether addr's subnet (its IP masked by its
+// Netmask) contains target, i.e. whether this interface plausibly routes
+// to it.
+func subnetContains(addr pcap.InterfaceAddress, target net.IP) bool {
+	if addr.Netmask == nil {
+		return false
+	}
+
+	subnet := &net.IPNet{IP: addr.IP.Mask(addr.Netmask), Mask: addr.Netmask}
+	return subnet.Contains(target)
+}
+
+// autoSelectInterface picks the single interface whose subnet contains the
+// default route toward targetIP, falling back to the first valid
+// interface if none match - replacing the old behavior of opening every
+// interface and spawning a goroutine per device.
+func autoSelectInterface(targetIP string) (string, error) {
+	infos, err := ListInterfaces(targetIP)
+	if err != nil {
+		return "", err
+	}
+
+	return selectInterface(infos)
+}
+
+// selectInterface implements autoSelectInterface's picking rule - prefer
+// the first likely route, else the first valid interface - as a pure
+// function over an already-built []InterfaceInfo, so it can be tested
+// without a live pcap device.
+func selectInterface(infos []InterfaceInfo) (string, error) {
+	for _, info := range infos {
+		if info.IsLikelyRoute {
+			return info.Name, nil
+		}
+	}
+
+	if len(infos) > 0 {
+		return infos[0].Name, nil
+	}
+
+	return "", fmt.Errorf("[Network.autoSelectInterface] no valid capture interface found")
+}
@@ -0,0 +1,105 @@
+package network
+
+import (
+	"net"
+	"testing"
+
+	"github.com/google/gopacket/pcap"
+)
+
+func TestSubnetContains(t *testing.T) {
+	tests := []struct {
+		name   string
+		addr   pcap.InterfaceAddress
+		target net.IP
+		want   bool
+	}{
+		{
+			name: "target inside /24",
+			addr: pcap.InterfaceAddress{
+				IP:      net.ParseIP("192.168.1.10"),
+				Netmask: net.CIDRMask(24, 32),
+			},
+			target: net.ParseIP("192.168.1.200"),
+			want:   true,
+		},
+		{
+			name: "target outside /24",
+			addr: pcap.InterfaceAddress{
+				IP:      net.ParseIP("192.168.1.10"),
+				Netmask: net.CIDRMask(24, 32),
+			},
+			target: net.ParseIP("192.168.2.200"),
+			want:   false,
+		},
+		{
+			name: "nil netmask never matches",
+			addr: pcap.InterfaceAddress{
+				IP:      net.ParseIP("192.168.1.10"),
+				Netmask: nil,
+			},
+			target: net.ParseIP("192.168.1.10"),
+			want:   false,
+		},
+		{
+			name: "IPv6 target inside /64",
+			addr: pcap.InterfaceAddress{
+				IP:      net.ParseIP("2001:db8::1"),
+				Netmask: net.CIDRMask(64, 128),
+			},
+			target: net.ParseIP("2001:db8::dead:beef"),
+			want:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := subnetContains(tt.addr, tt.target); got != tt.want {
+				t.Errorf("subnetContains() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectInterface(t *testing.T) {
+	tests := []struct {
+		name    string
+		infos   []InterfaceInfo
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "prefers the likely route over the first interface",
+			infos: []InterfaceInfo{
+				{Name: "eth0", IsLikelyRoute: false},
+				{Name: "eth1", IsLikelyRoute: true},
+			},
+			want: "eth1",
+		},
+		{
+			name: "falls back to the first valid interface when none match",
+			infos: []InterfaceInfo{
+				{Name: "eth0", IsLikelyRoute: false},
+				{Name: "eth1", IsLikelyRoute: false},
+			},
+			want: "eth0",
+		},
+		{
+			name:    "errors when there are no interfaces at all",
+			infos:   nil,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := selectInterface(tt.infos)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("selectInterface() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("selectInterface() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
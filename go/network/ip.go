@@ -0,0 +1,56 @@
+package network
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// resolveIPs resolves host - a literal IPv4/IPv6 address or a DNS name -
+// to every address PacketCaptureService should filter traffic for. A
+// literal is returned as-is; a hostname is resolved via net.LookupIP so a
+// server that publishes both A and AAAA records (or round-robins across
+// several addresses) is matched regardless of which one the OS happens to
+// return first.
+func resolveIPs(host string) ([]net.IP, error) {
+	if literal := net.ParseIP(host); literal != nil {
+		return []net.IP{literal}, nil
+	}
+
+	ips, err := net.LookupIP(host)
+	if err != nil {
+		return nil, fmt.Errorf("[Network.resolveIPs] unable to resolve %q: %w", host, err)
+	}
+
+	return ips, nil
+}
+
+// containsIP reports whether ips contains an address whose string form
+// (as produced by gopacket.Flow.Src()/Dst()) equals s.
+func containsIP(ips []net.IP, s string) bool {
+	for _, ip := range ips {
+		if ip.String() == s {
+			return true
+		}
+	}
+	return false
+}
+
+// buildFilter constructs a BPF filter string matching TCP traffic to/from
+// any of ips on port, e.g. "tcp and ((ip and host 1.2.3.4) or (ip6 and
+// host ::1)) and port 6900". Each address gets an explicit ip/ip6
+// qualifier - rather than reusing the old "net <ip>" form, which only
+// ever matched IPv4 - so a hostname that resolves to both A and AAAA
+// records is fully covered.
+func buildFilter(ips []net.IP, port int) string {
+	clauses := make([]string, 0, len(ips))
+	for _, ip := range ips {
+		if ip.To4() != nil {
+			clauses = append(clauses, fmt.Sprintf("(ip and host %s)", ip.String()))
+		} else {
+			clauses = append(clauses, fmt.Sprintf("(ip6 and host %s)", ip.String()))
+		}
+	}
+
+	return fmt.Sprintf("tcp and (%s) and port %d", strings.Join(clauses, " or "), port)
+}
@@ -0,0 +1,76 @@
+package network
+
+import (
+	"net"
+	"testing"
+)
+
+func TestBuildFilter(t *testing.T) {
+	tests := []struct {
+		name string
+		ips  []net.IP
+		port int
+		want string
+	}{
+		{
+			name: "single IPv4",
+			ips:  []net.IP{net.ParseIP("192.168.1.1")},
+			port: 6900,
+			want: "tcp and ((ip and host 192.168.1.1)) and port 6900",
+		},
+		{
+			name: "single IPv6",
+			ips:  []net.IP{net.ParseIP("2001:db8::1")},
+			port: 6900,
+			want: "tcp and ((ip6 and host 2001:db8::1)) and port 6900",
+		},
+		{
+			name: "dual-stack host with both an A and an AAAA record",
+			ips:  []net.IP{net.ParseIP("192.168.1.1"), net.ParseIP("2001:db8::1")},
+			port: 6900,
+			want: "tcp and ((ip and host 192.168.1.1) or (ip6 and host 2001:db8::1)) and port 6900",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := buildFilter(tt.ips, tt.port); got != tt.want {
+				t.Errorf("buildFilter() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveIPsLiteral(t *testing.T) {
+	tests := []struct {
+		name string
+		host string
+		want string
+	}{
+		{name: "IPv4 literal", host: "192.168.1.1", want: "192.168.1.1"},
+		{name: "IPv6 literal", host: "2001:db8::1", want: "2001:db8::1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ips, err := resolveIPs(tt.host)
+			if err != nil {
+				t.Fatalf("resolveIPs(%q) returned error: %v", tt.host, err)
+			}
+			if len(ips) != 1 || ips[0].String() != tt.want {
+				t.Errorf("resolveIPs(%q) = %v, want [%s]", tt.host, ips, tt.want)
+			}
+		})
+	}
+}
+
+func TestContainsIP(t *testing.T) {
+	ips := []net.IP{net.ParseIP("192.168.1.1"), net.ParseIP("2001:db8::1")}
+
+	if !containsIP(ips, "192.168.1.1") {
+		t.Error("containsIP() = false, want true for an address in the list")
+	}
+	if containsIP(ips, "10.0.0.1") {
+		t.Error("containsIP() = true, want false for an address not in the list")
+	}
+}
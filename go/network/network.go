@@ -2,41 +2,123 @@ package network
 
 import (
 	"fmt"
-	"log"
+	"net"
+	"os"
+	"sync"
 	"time"
 
 	"github.com/google/gopacket"
 	"github.com/google/gopacket/layers"
 	"github.com/google/gopacket/pcap"
+	"github.com/google/gopacket/pcapgo"
+	"github.com/google/gopacket/tcpassembly"
 	"golang.org/x/net/context"
 )
 
+// idleTimeout is how long a connection can go without a new segment before
+// the assembler flushes (and closes) it. This covers servers that never
+// send a clean FIN/RST, e.g. when the client side disconnects first.
+const idleTimeout = 30 * time.Second
+
 type PacketCaptureService struct {
-	ip                     string // IP address to filter packets
-	port                   int    // Port number to filter packets
+	ip                     string   // IP or hostname to filter packets, as configured
+	ips                    []net.IP // ip resolved to every matching address (A/AAAA records), via resolveIPs
+	port                   int      // Port number to filter packets
 	ctx                    context.Context
 	cancel                 context.CancelFunc
+	connMu                 sync.Mutex // guards connections, written from both the capture goroutine and flushIdleConnections
 	connections            map[ConnectionKey]*Connection
 	connCloseNotifyChannel chan *Connection
+	connNewNotifyChannel   chan *Connection
+	interfaceOpenedChannel chan string
+	errorChannel           chan error
+
+	// assemblerMu serializes every call into assembler: tcpassembly.Assembler
+	// is not safe for concurrent use (its page cache and internal reassembly
+	// buffer are Assembler-level, not per-connection, state), but
+	// AssembleWithTimestamp (from the capture/replay goroutine) and
+	// FlushOlderThan (from flushIdleConnections' own goroutine) would
+	// otherwise race on the same *Assembler.
+	assemblerMu sync.Mutex
+	assembler   *tcpassembly.Assembler
+
+	// offlinePath is set by NewOfflineCaptureService to replay a saved
+	// trace instead of sniffing a live interface; see offline.go.
+	offlinePath string
+
+	// recording mirrors every captured packet to a rotating sequence of
+	// .pcap files once EnableRecording has been called; see recording.go.
+	recordDir      string
+	recordBase     string
+	recordPart     int
+	recordLinkType layers.LinkType
+	recordBytes    int64
+	recordOnce     sync.Once
+	recordMu       sync.Mutex
+	recordFile     *os.File
+	recordWriter   *pcapgo.Writer
 }
 
 func NewPacketCaptureService(ip string, port int) *PacketCaptureService {
+	return newPacketCaptureService(ip, port)
+}
+
+// newPacketCaptureService builds the shared state used by both a live
+// (NewPacketCaptureService) and an offline (NewOfflineCaptureService)
+// capture service; only how packets are fed into handlePacket differs.
+func newPacketCaptureService(ip string, port int) *PacketCaptureService {
 	ctx, cancel := context.WithCancel(context.Background())
 
-	return &PacketCaptureService{
+	// resolve the configured host up front so a DNS name in config.xml is
+	// matched against every A/AAAA record it returns, not just whichever
+	// address the OS happens to try first; resolution failures are
+	// reported lazily (as a capture:error) once capture actually starts.
+	ips, _ := resolveIPs(ip)
+
+	pcs := &PacketCaptureService{
 		ip:                     ip,
+		ips:                    ips,
 		port:                   port,
 		ctx:                    ctx,
 		cancel:                 cancel,
 		connections:            make(map[ConnectionKey]*Connection),
 		connCloseNotifyChannel: make(chan *Connection),
+		connNewNotifyChannel:   make(chan *Connection),
+		interfaceOpenedChannel: make(chan string),
+		errorChannel:           make(chan error),
 	}
+
+	streamPool := tcpassembly.NewStreamPool(&streamFactory{pcs: pcs})
+	pcs.assembler = tcpassembly.NewAssembler(streamPool)
+
+	return pcs
 }
 
 func (pcs *PacketCaptureService) GetConnectionCloseNotifyChannel() chan *Connection {
 	return pcs.connCloseNotifyChannel
 }
 
+// GetConnectionNewNotifyChannel returns the channel a new Connection is
+// sent on as soon as streamFactory observes its first server->client
+// segment, letting callers surface per-connection progress before it
+// closes.
+func (pcs *PacketCaptureService) GetConnectionNewNotifyChannel() chan *Connection {
+	return pcs.connNewNotifyChannel
+}
+
+// GetInterfaceOpenedChannel returns the channel the selected interface's
+// name is sent on once it has been opened and its BPF filter applied.
+func (pcs *PacketCaptureService) GetInterfaceOpenedChannel() chan string {
+	return pcs.interfaceOpenedChannel
+}
+
+// GetErrorChannel returns the channel non-fatal capture errors (failing to
+// open a device, failing to set its filter, ...) are sent on instead of
+// crashing the process.
+func (pcs *PacketCaptureService) GetErrorChannel() chan error {
+	return pcs.errorChannel
+}
+
 func (pcs *PacketCaptureService) GetContext() context.Context {
 	return pcs.ctx
 }
@@ -46,79 +128,146 @@ func (pcs *PacketCaptureService) GetContext() context.Context {
 // and monitoring operations.
 func (pcs *PacketCaptureService) StopCapture() {
 	pcs.cancel()
+
+	pcs.recordMu.Lock()
+	if pcs.recordFile != nil {
+		pcs.recordFile.Close()
+	}
+	pcs.recordMu.Unlock()
 }
 
-// StartCaptureAllInterfaces initiates packet capture on all available network interfaces except loopback.
-// It performs the following steps for each non-loopback interface:
-// 1. Opens the interface for live packet capture
-// 2. Applies the configured BPF filter
-// 3. Starts a goroutine to continuously capture packets
-//
-// The captured packets are sent to the packetReceivedChannel for processing.
-// The capture can be stopped by canceling the context provided to the PacketCaptureService.
-//
-// This method runs asynchronously and does not block. Each interface capture runs in its own goroutine.
-// If there are errors opening devices or setting filters, they will be logged as fatal errors.
+// StartCaptureAllInterfaces auto-selects the single interface whose
+// subnet plausibly routes to pcs.ip (see autoSelectInterface) and starts
+// capturing on it. It used to open every non-loopback interface and spawn
+// a goroutine per device, which on Windows machines with many virtual
+// adapters (VPN, WSL, Hyper-V, VirtualBox) wasted handles and could
+// trigger UAC/driver warnings; callers that want to pick a specific
+// interface themselves should use StartCaptureOnInterface instead.
 func (pcs *PacketCaptureService) StartCaptureAllInterfaces() {
-	// first, find all network interfaces with pcap library
-	devices, err := pcap.FindAllDevs()
+	ifaceName, err := autoSelectInterface(pcs.ip)
 	if err != nil {
+		pcs.emitError(fmt.Errorf("[Network.StartCaptureAllInterfaces] %w", err))
 		return
 	}
 
-	// build filter for packet capture
-	filter := fmt.Sprintf("tcp and net %s and port %d", pcs.ip, pcs.port)
-	fmt.Printf("[Network.StartCaptureAllInterfaces] build filter success: %s", filter)
+	pcs.StartCaptureOnInterface(ifaceName)
+}
 
-	// then, iterate through all interfaces and capture packets
-	for _, device := range devices {
-		// fmt.Printf("Device %d: %s\n", index, device.Name)
+// StartCaptureOnInterface opens the named interface (as returned by
+// ListInterfaces) for live packet capture, applies the configured BPF
+// filter, and starts a goroutine that feeds captured packets into the
+// shared tcpassembly.Assembler, which reassembles each TCP 4-tuple's
+// server->client byte stream (reordering retransmitted/out-of-order
+// segments) before connCloseNotifyChannel fires. A separate goroutine
+// periodically flushes connections that have gone idle past idleTimeout
+// so a missing FIN/RST doesn't leak them forever.
+//
+// This method runs asynchronously and does not block. If there are errors
+// opening the device or setting the filter, they are sent on the channel
+// returned by GetErrorChannel rather than crashing the process.
+func (pcs *PacketCaptureService) StartCaptureOnInterface(ifaceName string) {
+	if len(pcs.ips) == 0 {
+		pcs.emitError(fmt.Errorf("[Network.StartCaptureOnInterface] unable to resolve address for %q", pcs.ip))
+		return
+	}
+
+	// build filter for packet capture, covering every A/AAAA record pcs.ip resolved to
+	filter := buildFilter(pcs.ips, pcs.port)
+	fmt.Printf("[Network.StartCaptureOnInterface] build filter success: %s", filter)
+
+	go pcs.flushIdleConnections()
+
+	go func() {
+		// open the device for live capture
+		handle, err := pcap.OpenLive(ifaceName, 1600, true, pcap.BlockForever)
+		if err != nil {
+			pcs.emitError(fmt.Errorf("[Network.StartCaptureOnInterface] unable to open network device %q: %w", ifaceName, err))
+			return
+		}
+		defer handle.Close()
 
-		// if the interface is not valid, skip it
-		if !IsValidInterface(device) {
-			continue
+		// set the BPF filter
+		err = handle.SetBPFFilter(filter)
+		if err != nil {
+			pcs.emitError(fmt.Errorf("[Network.StartCaptureOnInterface] unable to set filter: %w", err))
+			return
 		}
 
+		// lazily open the recording sink now that the handle (and thus its
+		// link type) is known
+		pcs.openRecordingSink(handle.LinkType())
+
 		go func() {
-			// open the device for live capture
-			handle, err := pcap.OpenLive(device.Name, 1600, true, pcap.BlockForever)
-			if err != nil {
-				log.Fatal("[Network.StartCaptureAllInterfaces] Unable to open network device:", err)
-				return
+			select {
+			case pcs.interfaceOpenedChannel <- ifaceName:
+			case <-pcs.ctx.Done():
 			}
-			defer handle.Close()
+		}()
+
+		fmt.Printf("[Network.StartCaptureOnInterface] Start sniffing on interface: %s\n", ifaceName)
+		// start capturing packets
+		packetSource := gopacket.NewPacketSource(handle, handle.LinkType())
+		packetSource.NoCopy = true
 
-			// set the BPF filter
-			err = handle.SetBPFFilter(filter)
-			if err != nil {
-				log.Fatal("[Network.StartCaptureAllInterfaces] Unable to set filter:", err)
+		for {
+			select {
+			case <-pcs.ctx.Done(): // listen for cancellation
 				return
+			case packet := <-packetSource.Packets():
+				pcs.handlePacket(packet)
 			}
+		}
+	}()
+}
 
-			fmt.Printf("[Network.StartCaptureAllInterfaces] Start sniffing on interface: %s\n", device.Name)
-			// start capturing packets
-			packetSource := gopacket.NewPacketSource(handle, handle.LinkType())
-			packetSource.NoCopy = true
-
-			for {
-				select {
-				case <-pcs.ctx.Done(): // listen for cancellation
-					return
-				case packet := <-packetSource.Packets():
-					pcs.handlePacket(packet)
-				}
-			}
-		}()
+// emitError sends err on errorChannel without blocking the caller. The
+// send is bounded by the service's context so that, if nobody ever reads
+// errorChannel for this capture session, the spawned goroutine exits with
+// it instead of leaking for the life of the process.
+func (pcs *PacketCaptureService) emitError(err error) {
+	go func() {
+		select {
+		case pcs.errorChannel <- err:
+		case <-pcs.ctx.Done():
+		}
+	}()
+}
+
+// flushIdleConnections periodically asks the assembler to flush (and thus
+// close) any connection that hasn't seen a segment in idleTimeout, until
+// the service's context is canceled. This runs on its own goroutine while
+// AssembleWithTimestamp runs on the capture (or replay) goroutine, so both
+// take pcs.assemblerMu - tcpassembly.Assembler itself must not be called
+// into concurrently - rather than relying on the assembler to serialize
+// itself.
+func (pcs *PacketCaptureService) flushIdleConnections() {
+	ticker := time.NewTicker(idleTimeout / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-pcs.ctx.Done():
+			return
+		case <-ticker.C:
+			pcs.assemblerMu.Lock()
+			pcs.assembler.FlushOlderThan(time.Now().Add(-idleTimeout))
+			pcs.assemblerMu.Unlock()
+		}
 	}
 }
 
 func (pcs *PacketCaptureService) handlePacket(packet gopacket.Packet) {
-	// extract IP layer
-	ipLayer := packet.Layer(layers.LayerTypeIPv4)
-	if ipLayer == nil {
+	// extract the IP layer, trying IPv4 first and falling back to IPv6 so
+	// a dual-stack server (or one only reachable over IPv6) isn't silently
+	// ignored
+	var netFlow gopacket.Flow
+	if ipLayer := packet.Layer(layers.LayerTypeIPv4); ipLayer != nil {
+		netFlow = ipLayer.(*layers.IPv4).NetworkFlow()
+	} else if ip6Layer := packet.Layer(layers.LayerTypeIPv6); ip6Layer != nil {
+		netFlow = ip6Layer.(*layers.IPv6).NetworkFlow()
+	} else {
 		return
 	}
-	ip, _ := ipLayer.(*layers.IPv4)
 
 	// extract TCP layer
 	tcpLayer := packet.Layer(layers.LayerTypeTCP)
@@ -127,50 +276,14 @@ func (pcs *PacketCaptureService) handlePacket(packet gopacket.Packet) {
 	}
 	tcp, _ := tcpLayer.(*layers.TCP)
 
-	// if the direction of the packet is NOT incoming, ignoring
-	if ip.SrcIP.String() != pcs.ip || tcp.SrcPort != layers.TCPPort(pcs.port) {
-		return
-	}
+	pcs.recordPacket(packet)
 
-	// create Connection instance
-	conn := &Connection{
-		SrcIP:   ip.SrcIP,
-		DstIP:   ip.DstIP,
-		SrcPort: uint16(tcp.SrcPort),
-		DstPort: uint16(tcp.DstPort),
-	}
-	key := conn.Key()
-
-	// check if the connection is already in the map
-	var existingConn *Connection
-
-	if existing, exists := pcs.connections[key]; exists {
-		existingConn = existing
-	} else {
-		// new connection
-		conn.StartTime = time.Now()
-		conn.LastSeen = time.Now()
-		pcs.connections[key] = conn
-		existingConn = conn
-
-		fmt.Printf("[NEW CONNECTION] %s:%d -> %s:%d\n",
-			conn.SrcIP, conn.SrcPort, conn.DstIP, conn.DstPort)
-	}
-
-	// update the last seen value of the existing connection
-	existingConn.LastSeen = time.Now()
-
-	// if the payload is not empty, recording it
-	payload := tcp.Payload
-	if len(payload) > 0 {
-		// copy payload and append to the IncomingPackets slice
-		data := make([]byte, len(payload))
-		copy(data, payload)
-		existingConn.IncomingData = append(existingConn.IncomingData, data)
-	}
-
-	// if the packet is a FIN or RST meaning the connection is about to close
-	if tcp.FIN || tcp.RST {
-		// TODO:
-	}
+	// hand the segment to the assembler, which dispatches it to the
+	// matching stream (creating one via streamFactory.New if needed) and
+	// reassembles it in order; tcpStream.Reassembled/ReassemblyComplete
+	// do the actual bookkeeping on Connection. Guarded by assemblerMu so
+	// this never races with flushIdleConnections' FlushOlderThan calls.
+	pcs.assemblerMu.Lock()
+	pcs.assembler.AssembleWithTimestamp(netFlow, tcp, packet.Metadata().CaptureInfo.Timestamp)
+	pcs.assemblerMu.Unlock()
 }
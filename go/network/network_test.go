@@ -0,0 +1,73 @@
+package network
+
+import (
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+// TestAssemblerConcurrentAccessIsSerialized exercises the exact hazard
+// flushIdleConnections and handlePacket share in production: one
+// goroutine feeding out-of-order/retransmitted TCP segments into the
+// assembler via AssembleWithTimestamp while another concurrently calls
+// FlushOlderThan on the same *tcpassembly.Assembler. Both take
+// pcs.assemblerMu, mirroring the locking in handlePacket and
+// flushIdleConnections. Run with `go test -race`, this must pass clean -
+// without the lock, tcpassembly.Assembler's internal page cache and
+// reassembly buffer race under the detector almost immediately.
+func TestAssemblerConcurrentAccessIsSerialized(t *testing.T) {
+	pcs := newPacketCaptureService("1.2.3.4", 1)
+	defer pcs.cancel()
+
+	netFlow, err := gopacket.FlowFromEndpoints(
+		layers.NewIPEndpoint(net.IP{1, 2, 3, 4}),
+		layers.NewIPEndpoint(net.IP{5, 6, 7, 8}),
+	)
+	if err != nil {
+		t.Fatalf("FlowFromEndpoints: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	// out-of-order/retransmitted segments - the scenario this request
+	// exists to reassemble correctly.
+	seqs := []uint32{1001, 1007, 1004, 1010, 1013, 1004}
+
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 500; i++ {
+			tcp := &layers.TCP{
+				SrcPort:   1,
+				DstPort:   2,
+				Seq:       seqs[i%len(seqs)],
+				BaseLayer: layers.BaseLayer{Payload: []byte{byte(i)}},
+			}
+			tcp.SetInternalPortsForTesting()
+
+			pcs.assemblerMu.Lock()
+			pcs.assembler.AssembleWithTimestamp(netFlow, tcp, time.Now())
+			pcs.assemblerMu.Unlock()
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 500; i++ {
+			// flush everything buffered so far, not just genuinely idle
+			// connections (idleTimeout is 30s, far longer than this test
+			// runs) - this is what actually drives FlushOlderThan into
+			// the same page-cache/reassembly-buffer state AssembleWithTimestamp
+			// is concurrently mutating.
+			pcs.assemblerMu.Lock()
+			pcs.assembler.FlushOlderThan(time.Now().Add(time.Hour))
+			pcs.assemblerMu.Unlock()
+		}
+	}()
+
+	wg.Wait()
+}
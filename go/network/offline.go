@@ -0,0 +1,59 @@
+package network
+
+import (
+	"fmt"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/pcap"
+)
+
+// NewOfflineCaptureService creates a PacketCaptureService that replays an
+// already-captured .pcap file (e.g. one saved via EnableRecording, or
+// attached to a bug report) through the same handlePacket/tcpassembly
+// pipeline as a live capture, instead of sniffing a live interface. Call
+// StartReplay to begin reading it.
+func NewOfflineCaptureService(pcapPath string, ip string, port int) *PacketCaptureService {
+	pcs := newPacketCaptureService(ip, port)
+	pcs.offlinePath = pcapPath
+	return pcs
+}
+
+// StartReplay reads every packet from the offline pcap file set via
+// NewOfflineCaptureService through the same reassembly pipeline used by
+// live capture, then cancels the service's context once the file is
+// exhausted so callers blocked on GetContext().Done() can return.
+func (pcs *PacketCaptureService) StartReplay() error {
+	handle, err := pcap.OpenOffline(pcs.offlinePath)
+	if err != nil {
+		return fmt.Errorf("[Network.StartReplay] unable to open pcap file %q: %w", pcs.offlinePath, err)
+	}
+	defer handle.Close()
+
+	if len(pcs.ips) == 0 {
+		return fmt.Errorf("[Network.StartReplay] unable to resolve address for %q", pcs.ip)
+	}
+
+	filter := buildFilter(pcs.ips, pcs.port)
+	if err := handle.SetBPFFilter(filter); err != nil {
+		return fmt.Errorf("[Network.StartReplay] unable to set filter: %w", err)
+	}
+
+	packetSource := gopacket.NewPacketSource(handle, handle.LinkType())
+
+	for packet := range packetSource.Packets() {
+		select {
+		case <-pcs.ctx.Done():
+			return nil
+		default:
+		}
+		pcs.handlePacket(packet)
+	}
+
+	// the file is exhausted: flush whatever connections are still open so
+	// their ReassemblyComplete notification fires, then stop the service
+	pcs.assemblerMu.Lock()
+	pcs.assembler.FlushAll()
+	pcs.assemblerMu.Unlock()
+	pcs.cancel()
+	return nil
+}
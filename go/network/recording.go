@@ -0,0 +1,111 @@
+package network
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcapgo"
+)
+
+// recordingRotateSize caps how large a single recorded .pcap file is
+// allowed to grow before recordPacket rolls over to the next part, so
+// a long-running capture session doesn't grow one file without bound.
+const recordingRotateSize = 100 * 1024 * 1024 // 100 MiB
+
+// EnableRecording turns on mirroring of every captured packet to a
+// rotating sequence of timestamped .pcap files inside dir -
+// capture-<ts>-001.pcap, capture-<ts>-002.pcap, ... rolling over once the
+// current part reaches recordingRotateSize - so a bug report can later be
+// reproduced offline via NewOfflineCaptureService instead of trying to
+// reproduce live server behavior. It must be called before
+// StartCaptureAllInterfaces; the first part is created lazily, once the
+// first interface handle (and thus its link type) is known.
+func (pcs *PacketCaptureService) EnableRecording(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("[Network.EnableRecording] unable to create recording dir: %w", err)
+	}
+
+	pcs.recordDir = dir
+	pcs.recordBase = fmt.Sprintf("capture-%d", time.Now().UnixNano())
+	return nil
+}
+
+// openRecordingSink opens the first recording part for EnableRecording
+// the first time it's called, remembering linkType so later rotations
+// can reuse it for their own pcap file header. It is a no-op if recording
+// wasn't enabled or a sink is already open.
+func (pcs *PacketCaptureService) openRecordingSink(linkType layers.LinkType) {
+	if pcs.recordBase == "" {
+		return
+	}
+
+	pcs.recordOnce.Do(func() {
+		pcs.recordLinkType = linkType
+
+		pcs.recordMu.Lock()
+		defer pcs.recordMu.Unlock()
+		pcs.openNextRecordingPart()
+	})
+}
+
+// openNextRecordingPart closes the current recording file (if any) and
+// opens the next numbered part, e.g. capture-<ts>-002.pcap. Callers must
+// hold recordMu.
+func (pcs *PacketCaptureService) openNextRecordingPart() {
+	if pcs.recordFile != nil {
+		pcs.recordFile.Close()
+	}
+	pcs.recordFile = nil
+	pcs.recordWriter = nil
+
+	pcs.recordPart++
+	path := filepath.Join(pcs.recordDir, fmt.Sprintf("%s-%03d.pcap", pcs.recordBase, pcs.recordPart))
+
+	f, err := os.Create(path)
+	if err != nil {
+		log.Printf("[Network.openNextRecordingPart] unable to create recording file: %v", err)
+		return
+	}
+
+	w := pcapgo.NewWriter(f)
+	if err := w.WriteFileHeader(1600, pcs.recordLinkType); err != nil {
+		log.Printf("[Network.openNextRecordingPart] unable to write pcap header: %v", err)
+		f.Close()
+		return
+	}
+
+	pcs.recordFile = f
+	pcs.recordWriter = w
+	pcs.recordBytes = 0
+}
+
+// recordPacket mirrors packet to the recording sink, if one is open,
+// rotating to a new part first if the current one has grown past
+// recordingRotateSize.
+func (pcs *PacketCaptureService) recordPacket(packet gopacket.Packet) {
+	pcs.recordMu.Lock()
+	defer pcs.recordMu.Unlock()
+
+	if pcs.recordWriter == nil {
+		return
+	}
+
+	if pcs.recordBytes >= recordingRotateSize {
+		pcs.openNextRecordingPart()
+		if pcs.recordWriter == nil {
+			return
+		}
+	}
+
+	if err := pcs.recordWriter.WritePacket(packet.Metadata().CaptureInfo, packet.Data()); err != nil {
+		log.Printf("[Network.recordPacket] unable to write packet: %v", err)
+		return
+	}
+
+	pcs.recordBytes += int64(len(packet.Data()))
+}
@@ -0,0 +1,99 @@
+package network
+
+import (
+	"encoding/binary"
+	"net"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/tcpassembly"
+)
+
+// streamFactory implements tcpassembly.StreamFactory. tcpassembly calls New
+// once per direction of a TCP connection; we only care about the
+// server->client direction (source address in PacketCaptureService.ips,
+// source port matching PacketCaptureService.port), so the client->server
+// direction is handed a discardStream that keeps the assembler's
+// sequencing happy without retaining any bytes.
+type streamFactory struct {
+	pcs *PacketCaptureService
+}
+
+func (f *streamFactory) New(netFlow, transport gopacket.Flow) tcpassembly.Stream {
+	srcPort := binary.BigEndian.Uint16(transport.Src().Raw())
+
+	if !containsIP(f.pcs.ips, netFlow.Src().String()) || int(srcPort) != f.pcs.port {
+		return &discardStream{}
+	}
+
+	conn := &Connection{
+		SrcIP:     net.IP(netFlow.Src().Raw()),
+		DstIP:     net.IP(netFlow.Dst().Raw()),
+		SrcPort:   srcPort,
+		DstPort:   binary.BigEndian.Uint16(transport.Dst().Raw()),
+		StartTime: time.Now(),
+		LastSeen:  time.Now(),
+	}
+
+	f.pcs.connMu.Lock()
+	f.pcs.connections[conn.Key()] = conn
+	f.pcs.connMu.Unlock()
+
+	// notify asynchronously so a slow (or absent) receiver on
+	// connNewNotifyChannel never blocks the assembler goroutine; bounded by
+	// the service's context so an absent receiver doesn't leak the
+	// goroutine for the life of the process.
+	go func() {
+		select {
+		case f.pcs.connNewNotifyChannel <- conn:
+		case <-f.pcs.ctx.Done():
+		}
+	}()
+
+	return &tcpStream{pcs: f.pcs, conn: conn}
+}
+
+// tcpStream accumulates one server->client TCP connection's reassembled
+// bytes into its Connection and notifies PacketCaptureService once
+// tcpassembly considers the stream finished (FIN/RST seen, or flushed out
+// after sitting idle past idleTimeout).
+type tcpStream struct {
+	pcs  *PacketCaptureService
+	conn *Connection
+}
+
+func (s *tcpStream) Reassembled(reassembly []tcpassembly.Reassembly) {
+	for _, r := range reassembly {
+		if len(r.Bytes) == 0 {
+			continue
+		}
+		s.conn.LastSeen = time.Now()
+		s.conn.IncomingData.Write(r.Bytes)
+	}
+}
+
+func (s *tcpStream) ReassemblyComplete() {
+	s.conn.IsFinished = true
+
+	s.pcs.connMu.Lock()
+	delete(s.pcs.connections, s.conn.Key())
+	s.pcs.connMu.Unlock()
+
+	// notify asynchronously so a slow (or absent) receiver on
+	// connCloseNotifyChannel never blocks the assembler goroutine; bounded
+	// by the service's context so an absent receiver doesn't leak the
+	// goroutine for the life of the process.
+	go func() {
+		select {
+		case s.pcs.connCloseNotifyChannel <- s.conn:
+		case <-s.pcs.ctx.Done():
+		}
+	}()
+}
+
+// discardStream implements tcpassembly.Stream for the client->server
+// direction, which we never need to inspect.
+type discardStream struct{}
+
+func (d *discardStream) Reassembled(reassembly []tcpassembly.Reassembly) {}
+func (d *discardStream) ReassemblyComplete()                             {}
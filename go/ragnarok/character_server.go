@@ -0,0 +1,67 @@
+package ragnarok
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+// CharacterServerInfo describes one character-server entry parsed out of
+// a login server's server-list response.
+type CharacterServerInfo struct {
+	IP        string
+	Port      uint16
+	Name      string
+	UserCount uint16
+}
+
+// characterServerEntrySize is the byte length of one character-server
+// entry: a 4-byte IP, 2-byte port, 20-byte padded name and 2-byte user
+// count.
+const characterServerEntrySize = 28
+
+// ParsePayloadToCharacterServerInfo scans payload for every occurrence of
+// pattern (the login server's private IP prefix, e.g. {0xc0, 0xa8} for a
+// 192.168.x.x response) and decodes the fixed-size character-server entry
+// that starts at each one (the pattern is the first bytes of the entry's
+// IP). It returns nil if pattern never occurs in
+// payload or no entry after it could be decoded.
+func ParsePayloadToCharacterServerInfo(payload []byte, pattern []byte) []CharacterServerInfo {
+	if len(pattern) == 0 {
+		return nil
+	}
+
+	var servers []CharacterServerInfo
+
+	for offset := 0; offset < len(payload); {
+		idx := bytes.Index(payload[offset:], pattern)
+		if idx == -1 {
+			break
+		}
+		entryStart := offset + idx
+
+		if server, ok := decodeCharacterServerEntry(payload[entryStart:]); ok {
+			servers = append(servers, server)
+		}
+
+		offset = entryStart + len(pattern)
+	}
+
+	return servers
+}
+
+// decodeCharacterServerEntry decodes one character-server entry from the
+// start of entry, reporting false if entry is too short to hold one.
+func decodeCharacterServerEntry(entry []byte) (CharacterServerInfo, bool) {
+	if len(entry) < characterServerEntrySize {
+		return CharacterServerInfo{}, false
+	}
+
+	ip := fmt.Sprintf("%d.%d.%d.%d", entry[0], entry[1], entry[2], entry[3])
+	port := binary.LittleEndian.Uint16(entry[4:6])
+	name := strings.TrimRight(string(entry[6:26]), "\x00")
+	userCount := binary.LittleEndian.Uint16(entry[26:28])
+
+	return CharacterServerInfo{IP: ip, Port: port, Name: name, UserCount: userCount}, true
+}
@@ -0,0 +1,34 @@
+package ragnarok
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// CharacterServerDissector is the built-in Dissector for the
+// character-server list payload, keyed by a LoginServer's Pattern and
+// IsNumberResponse, preserving the behavior
+// ParsePayloadToCharacterServerInfo had before the Registry existed.
+// Match and Parse use Pattern only; IsNumberResponse is stored on the
+// dissector but neither method reads it.
+type CharacterServerDissector struct {
+	Pattern          []byte
+	IsNumberResponse bool
+}
+
+func (d *CharacterServerDissector) Name() string {
+	return "character-server-list"
+}
+
+func (d *CharacterServerDissector) Match(payload []byte) bool {
+	return bytes.Contains(payload, d.Pattern)
+}
+
+func (d *CharacterServerDissector) Parse(payload []byte) (any, error) {
+	servers := ParsePayloadToCharacterServerInfo(payload, d.Pattern)
+	if servers == nil {
+		return nil, fmt.Errorf("[Ragnarok.CharacterServerDissector.Parse] no character-server entries found")
+	}
+
+	return servers, nil
+}
@@ -0,0 +1,110 @@
+package ragnarok
+
+import (
+	"reflect"
+	"testing"
+)
+
+// buildEntry builds one 28-byte character-server entry: a 4-byte IP,
+// 2-byte little-endian port, 20-byte null-padded name and 2-byte
+// little-endian user count.
+func buildEntry(ip [4]byte, port uint16, name string, userCount uint16) []byte {
+	entry := make([]byte, characterServerEntrySize)
+	copy(entry[0:4], ip[:])
+	entry[4] = byte(port)
+	entry[5] = byte(port >> 8)
+	copy(entry[6:26], name)
+	entry[26] = byte(userCount)
+	entry[27] = byte(userCount >> 8)
+	return entry
+}
+
+func TestDecodeCharacterServerEntry(t *testing.T) {
+	t.Run("decodes a well-formed entry", func(t *testing.T) {
+		entry := buildEntry([4]byte{192, 168, 1, 10}, 6121, "Server1", 42)
+
+		got, ok := decodeCharacterServerEntry(entry)
+		if !ok {
+			t.Fatal("decodeCharacterServerEntry() ok = false, want true")
+		}
+
+		want := CharacterServerInfo{IP: "192.168.1.10", Port: 6121, Name: "Server1", UserCount: 42}
+		if got != want {
+			t.Errorf("decodeCharacterServerEntry() = %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("reports false for a truncated entry", func(t *testing.T) {
+		_, ok := decodeCharacterServerEntry(make([]byte, characterServerEntrySize-1))
+		if ok {
+			t.Error("decodeCharacterServerEntry() ok = true, want false for a short entry")
+		}
+	})
+}
+
+func TestParsePayloadToCharacterServerInfo(t *testing.T) {
+	pattern := []byte{0xc0, 0xa8} // 192.168.x.x
+
+	t.Run("decodes every entry matching pattern", func(t *testing.T) {
+		entry1 := buildEntry([4]byte{192, 168, 1, 10}, 6121, "Server1", 10)
+		entry2 := buildEntry([4]byte{192, 168, 1, 11}, 6121, "Server2", 20)
+
+		payload := append(append([]byte{0x00, 0x00}, entry1...), entry2...)
+
+		got := ParsePayloadToCharacterServerInfo(payload, pattern)
+		want := []CharacterServerInfo{
+			{IP: "192.168.1.10", Port: 6121, Name: "Server1", UserCount: 10},
+			{IP: "192.168.1.11", Port: 6121, Name: "Server2", UserCount: 20},
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("ParsePayloadToCharacterServerInfo() = %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("returns nil when the pattern never occurs", func(t *testing.T) {
+		if got := ParsePayloadToCharacterServerInfo([]byte{0x01, 0x02, 0x03}, pattern); got != nil {
+			t.Errorf("ParsePayloadToCharacterServerInfo() = %+v, want nil", got)
+		}
+	})
+
+	t.Run("returns nil for an empty pattern", func(t *testing.T) {
+		if got := ParsePayloadToCharacterServerInfo([]byte{0x01, 0x02, 0x03}, nil); got != nil {
+			t.Errorf("ParsePayloadToCharacterServerInfo() = %+v, want nil", got)
+		}
+	})
+
+	t.Run("skips a pattern match too close to the end to hold an entry", func(t *testing.T) {
+		payload := append([]byte{0x00}, pattern...)
+		if got := ParsePayloadToCharacterServerInfo(payload, pattern); got != nil {
+			t.Errorf("ParsePayloadToCharacterServerInfo() = %+v, want nil", got)
+		}
+	})
+}
+
+func TestRegistryDissect(t *testing.T) {
+	pattern := []byte{0xc0, 0xa8}
+
+	registry := NewRegistry()
+	registry.Register(&CharacterServerDissector{Pattern: pattern, IsNumberResponse: true})
+
+	t.Run("returns a keyed result when a dissector matches", func(t *testing.T) {
+		entry := buildEntry([4]byte{192, 168, 1, 10}, 6121, "Server1", 10)
+
+		results := registry.Dissect(entry)
+		servers, ok := results["character-server-list"].([]CharacterServerInfo)
+		if !ok {
+			t.Fatalf("results[\"character-server-list\"] missing or wrong type: %+v", results)
+		}
+		if len(servers) != 1 || servers[0].Name != "Server1" {
+			t.Errorf("results[\"character-server-list\"] = %+v, want one Server1 entry", servers)
+		}
+	})
+
+	t.Run("returns an empty map when nothing matches", func(t *testing.T) {
+		results := registry.Dissect([]byte{0x01, 0x02, 0x03})
+		if len(results) != 0 {
+			t.Errorf("Dissect() = %+v, want empty map", results)
+		}
+	})
+}
@@ -0,0 +1,53 @@
+package ragnarok
+
+// Dissector recognizes and parses one kind of server->client packet out
+// of a reassembled TCP stream (char list, server list, MOTD, ping, world
+// status, kicked/duplicate-login notifications, ...).
+type Dissector interface {
+	// Name identifies the dissector, e.g. "character-server-list".
+	Name() string
+	// Match reports whether payload looks like this dissector's packet.
+	Match(payload []byte) bool
+	// Parse decodes payload into the dissector's result type.
+	Parse(payload []byte) (any, error)
+}
+
+// Registry holds a set of Dissectors to try against a reassembled
+// stream, so supporting a new server->client packet is a single
+// Register call instead of another special case in App.
+type Registry struct {
+	dissectors []Dissector
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds d to r.
+func (r *Registry) Register(d Dissector) {
+	r.dissectors = append(r.dissectors, d)
+}
+
+// Dissect runs every registered Dissector whose Match reports true
+// against payload and returns the successfully parsed results, keyed by
+// dissector name, so callers can emit a discriminated-union result
+// instead of special-casing one packet type.
+func (r *Registry) Dissect(payload []byte) map[string]any {
+	results := make(map[string]any)
+
+	for _, d := range r.dissectors {
+		if !d.Match(payload) {
+			continue
+		}
+
+		result, err := d.Parse(payload)
+		if err != nil {
+			continue
+		}
+
+		results[d.Name()] = result
+	}
+
+	return results
+}